@@ -0,0 +1,148 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command server runs the verification server: the device authorization
+// grant, JWKS, and key rotation endpoints, plus the login flow for whichever
+// Authenticator Config.AuthProvider selects.
+//
+// The template-rendered operator UI (the login page, the /device
+// confirmation page, flash messages) is not part of this tree - see the
+// doc comment on controller.DeviceController.HandleConfirm - so
+// routes that would otherwise render HTML just redirect or report a plain
+// text error instead. That gap predates this binary; this entrypoint wires
+// together every controller that does exist.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	gcontext "github.com/gorilla/context"
+	"github.com/gorilla/csrf"
+
+	"github.com/google/exposure-notifications-verification-server/pkg/config"
+	"github.com/google/exposure-notifications-verification-server/pkg/controller"
+	"github.com/google/exposure-notifications-verification-server/pkg/database"
+)
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.New(ctx)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	db, err := cfg.Database.Open(ctx)
+	if err != nil {
+		log.Fatalf("failed to open database: %v", err)
+	}
+
+	// Purge expired device authorizations every CleanupPeriod.
+	if cfg.CleanupPeriod > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.CleanupPeriod)
+			defer ticker.Stop()
+			for range ticker.C {
+				if _, err := db.PurgeExpiredDeviceAuths(time.Now().UTC()); err != nil {
+					log.Printf("failed to purge expired device authorizations: %v", err)
+				}
+			}
+		}()
+	}
+
+	auth, err := controller.NewAuthenticator(ctx, cfg, db)
+	if err != nil {
+		log.Fatalf("failed to initialize authenticator: %v", err)
+	}
+
+	publisher, err := cfg.EventPublisher(ctx)
+	if err != nil {
+		log.Fatalf("failed to initialize event publisher: %v", err)
+	}
+
+	tokenKeys, err := cfg.TokenKeySet(ctx)
+	if err != nil {
+		log.Fatalf("failed to resolve token signing key: %v", err)
+	}
+	certificateKeys, err := cfg.CertificateKeySet(ctx)
+	if err != nil {
+		log.Fatalf("failed to resolve certificate signing key: %v", err)
+	}
+
+	deviceController := controller.NewDeviceController(cfg, db, publisher)
+	jwksController := controller.NewJWKSController(tokenKeys, certificateKeys)
+	keyRotationController := controller.NewKeyRotationController(cfg)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", auth.Login)
+	mux.HandleFunc("/login/callback", loginCallback(auth))
+	mux.HandleFunc("/device/authorize", deviceController.HandleAuthorize)
+	mux.HandleFunc("/device/token", deviceController.HandleToken)
+	mux.HandleFunc("/device", deviceController.HandleConfirm)
+	mux.HandleFunc("/.well-known/jwks.json", jwksController.HandleIndex)
+	mux.Handle("/admin/keys/rotate", requireAdmin(http.HandlerFunc(keyRotationController.HandleRotate)))
+
+	csrfKey, err := cfg.CSRFKey()
+	if err != nil {
+		log.Fatalf("failed to load csrf key: %v", err)
+	}
+
+	var handler http.Handler = mux
+	handler = controller.RevokeCheckMiddleware(auth, cfg.RevokeCheckPeriod)(handler)
+	handler = csrf.Protect(csrfKey, csrf.Secure(!cfg.DevMode))(handler)
+	handler = gcontext.ClearHandler(handler)
+
+	addr := fmt.Sprintf(":%d", cfg.Port)
+	log.Printf("listening on %s", addr)
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		log.Fatalf("server exited: %v", err)
+	}
+}
+
+// loginCallback completes sign-in via auth and, on success, stashes the
+// resulting database.User in the request context under the "user" key
+// controller.GetUser reads from, mirroring however the rest of this
+// request's middleware chain is expected to persist it across requests.
+func loginCallback(auth controller.Authenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := auth.Callback(w, r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("sign in failed: %v", err), http.StatusUnauthorized)
+			return
+		}
+		gcontext.Set(r, "user", user)
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	}
+}
+
+// requireAdmin rejects any request not made by a signed-in database.RoleAdmin
+// user, so POST /admin/keys/rotate can't be hit by an ordinary user session.
+func requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := controller.GetUser(w, r)
+		if err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if user.Role != database.RoleAdmin {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// jwks fetches and parses the server's published JSON Web Key Set from
+// Config.SigningKeyJWKSURL.
+func jwks() (*jose.JSONWebKeySet, error) {
+	resp, err := http.Get(cfg.SigningKeyJWKSURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var set jose.JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+	return &set, nil
+}
+
+// verifyClaims parses and signature-verifies raw against the server's JWKS,
+// then asserts the standard claims every issued token/certificate must
+// carry: a not-before in the past, a not-yet-expired exp, and the expected
+// issuer/audience. wantAudience is only enforced when non-empty, since not
+// every token this suite checks carries one.
+func verifyClaims(raw, wantIssuer, wantAudience string) error {
+	set, err := jwks()
+	if err != nil {
+		return err
+	}
+
+	tok, err := jwt.ParseSigned(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	var claims jwt.Claims
+	verified := false
+	for _, key := range set.Keys {
+		if err := tok.Claims(key, &claims); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return fmt.Errorf("token signature did not verify against any published jwks key")
+	}
+
+	expected := jwt.Expected{
+		Issuer: wantIssuer,
+		Time:   time.Now(),
+	}
+	if wantAudience != "" {
+		expected.Audience = jwt.Audience{wantAudience}
+	}
+	if err := claims.Validate(expected); err != nil {
+		return fmt.Errorf("claim validation failed: %w", err)
+	}
+	return nil
+}
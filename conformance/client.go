@@ -0,0 +1,100 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// client is a thin wrapper around the verification server's JSON API, used
+// by every scenario in this suite.
+type client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+func newClient(baseURL, apiKey string) *client {
+	return &client{baseURL: baseURL, apiKey: apiKey, http: http.DefaultClient}
+}
+
+// issueCodeResponse mirrors the server's POST /api/issue response.
+type issueCodeResponse struct {
+	VerificationCode string `json:"verificationCode"`
+	ExpiresAt        string `json:"expiresAt"`
+	Error            string `json:"error,omitempty"`
+}
+
+func (c *client) issueCode(testDate string) (*issueCodeResponse, *http.Response, error) {
+	var out issueCodeResponse
+	resp, err := c.post("/api/issue", map[string]string{"testDate": testDate}, &out)
+	return &out, resp, err
+}
+
+// exchangeCodeResponse mirrors the server's POST /api/verify response.
+type exchangeCodeResponse struct {
+	Token     string `json:"token"`
+	TestType  string `json:"testtype"`
+	ExpiresAt string `json:"expiresAt"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (c *client) exchangeCode(code string) (*exchangeCodeResponse, *http.Response, error) {
+	var out exchangeCodeResponse
+	resp, err := c.post("/api/verify", map[string]string{"code": code}, &out)
+	return &out, resp, err
+}
+
+// exchangeTokenResponse mirrors the server's POST /api/certificate response.
+type exchangeTokenResponse struct {
+	Certificate string `json:"certificate"`
+	Error       string `json:"error,omitempty"`
+}
+
+func (c *client) exchangeToken(token, hmac string) (*exchangeTokenResponse, *http.Response, error) {
+	var out exchangeTokenResponse
+	resp, err := c.post("/api/certificate", map[string]string{"token": token, "ekeyhmac": hmac}, &out)
+	return &out, resp, err
+}
+
+func (c *client) post(path string, body interface{}, out interface{}) (*http.Response, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("failed to decode response from %s: %w", path, err)
+		}
+	}
+	return resp, nil
+}
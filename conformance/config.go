@@ -0,0 +1,37 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import "flag"
+
+// Config holds the flags this suite needs to talk to the server under test.
+// It is parsed once in TestConformance (see suite_test.go).
+type Config struct {
+	ServerURL         string
+	APIKey            string
+	AdminAPIKey       string
+	SigningKeyJWKSURL string
+	DevMode           bool
+}
+
+var cfg Config
+
+func init() {
+	flag.StringVar(&cfg.ServerURL, "server-url", "", "base URL of the verification server under test")
+	flag.StringVar(&cfg.APIKey, "api-key", "", "device API key used for issue/exchange requests")
+	flag.StringVar(&cfg.AdminAPIKey, "admin-api-key", "", "admin API key (not read by any scenario in this suite)")
+	flag.StringVar(&cfg.SigningKeyJWKSURL, "signing-key-jwks-url", "", "JWKS endpoint used to verify token/certificate signatures")
+	flag.BoolVar(&cfg.DevMode, "dev-mode", false, "whether the server under test is running with DEV_MODE=true, used to assert the CSRF cookie's Secure flag")
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CSRF cookie behavior", func() {
+	It("sets the CSRF cookie's Secure flag to match the server's DEV_MODE", func() {
+		resp, err := http.Get(cfg.ServerURL + "/login")
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		cookie := csrfCookie(resp)
+		Expect(cookie).ToNot(BeNil(), "expected a CSRF cookie on the login page")
+
+		// DEV_MODE=true serves the UI over plain HTTP, so the cookie must not
+		// be Secure-only there; DEV_MODE=false (the production default)
+		// requires Secure so the cookie is never sent over HTTP.
+		Expect(cookie.Secure).To(Equal(!cfg.DevMode), "csrf cookie Secure flag did not match --dev-mode=%v", cfg.DevMode)
+	})
+
+	It("rejects a login POST missing the CSRF token", func() {
+		resp, err := http.PostForm(cfg.ServerURL+"/login", map[string][]string{
+			"email":    {"conformance@example.com"},
+			"password": {"not-a-real-password"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+	})
+})
+
+func csrfCookie(resp *http.Response) *http.Cookie {
+	for _, c := range resp.Cookies() {
+		if c.Name == "_gorilla_csrf" {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conformance is a protocol-level regression suite that exercises a
+// live verification server over the wire: issue code -> exchange for token
+// -> exchange token for certificate. Any server that implements the
+// verification protocol - this repo or a fork of it - can run this suite
+// against itself to confirm it still speaks the protocol correctly.
+//
+// It is a separate Go module (see go.mod in this directory) so that
+// third-party implementations can `go get` and run it without pulling in
+// this repo's server-side dependencies.
+package conformance
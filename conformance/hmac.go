@@ -0,0 +1,36 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// fakeExposureKeys is a deterministic, made-up set of temporary exposure
+// keys used only to compute a well-formed ekeyhmac for the certificate
+// exchange scenarios. The server never sees the keys themselves - only the
+// ekeyhmac presented alongside the token at /api/certificate (/api/verify is
+// called with the code alone).
+var fakeExposureKeys = []byte("conformance-suite-fake-exposure-keys")
+
+// validTestHMAC computes the ekeyhmac this suite's fakeExposureKeys would
+// produce, for the happy-path certificate exchange scenario.
+func validTestHMAC() string {
+	mac := hmac.New(sha256.New, fakeExposureKeys)
+	mac.Write(fakeExposureKeys)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("issuance and redemption", func() {
+	var c *client
+
+	BeforeEach(func() {
+		c = newClient(cfg.ServerURL, cfg.APIKey)
+	})
+
+	It("issues a code and redeems it for a token on the happy path", func() {
+		issued, resp, err := c.issueCode(time.Now().Format("2006-01-02"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(issued.VerificationCode).ToNot(BeEmpty())
+
+		exchanged, resp, err := c.exchangeCode(issued.VerificationCode)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(exchanged.Token).ToNot(BeEmpty())
+	})
+
+	It("rejects an expired code", func() {
+		issued, _, err := c.issueCode(time.Now().Format("2006-01-02"))
+		Expect(err).ToNot(HaveOccurred())
+
+		// The suite can't fast-forward the server's clock, so this mints a
+		// fresh code and polls the exchange every 5s for up to 2m, waiting
+		// for a 400. Operators running this against a live instance should
+		// configure CODE_DURATION short enough to observe this within the
+		// suite's own timeout.
+		Eventually(func() int {
+			_, resp, _ := c.exchangeCode(issued.VerificationCode)
+			return resp.StatusCode
+		}, "2m", "5s").Should(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects a replayed code", func() {
+		issued, _, err := c.issueCode(time.Now().Format("2006-01-02"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, resp, err := c.exchangeCode(issued.VerificationCode)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		_, resp, err = c.exchangeCode(issued.VerificationCode)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -0,0 +1,40 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("rate limiting", func() {
+	It("eventually rejects issuance once RATE_LIMIT is exceeded", func() {
+		c := newClient(cfg.ServerURL, cfg.APIKey)
+
+		var sawRateLimited bool
+		for i := 0; i < 200; i++ {
+			_, resp, err := c.issueCode(time.Now().Format("2006-01-02"))
+			Expect(err).ToNot(HaveOccurred())
+			if resp.StatusCode == http.StatusTooManyRequests {
+				sawRateLimited = true
+				break
+			}
+		}
+		Expect(sawRateLimited).To(BeTrue(), "expected at least one 429 within 200 issuance requests")
+	})
+})
@@ -0,0 +1,68 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("token and certificate claim validation", func() {
+	var c *client
+
+	BeforeEach(func() {
+		c = newClient(cfg.ServerURL, cfg.APIKey)
+	})
+
+	It("issues a token whose nbf/exp/aud/iss survive claim validation", func() {
+		issued, _, err := c.issueCode(time.Now().Format("2006-01-02"))
+		Expect(err).ToNot(HaveOccurred())
+
+		exchanged, _, err := c.exchangeCode(issued.VerificationCode)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exchanged.Token).ToNot(BeEmpty())
+
+		Expect(verifyClaims(exchanged.Token, "diagnosis-verification-example", "")).To(Succeed())
+	})
+
+	It("rejects exchanging a token presented with the wrong ekeyhmac", func() {
+		issued, _, err := c.issueCode(time.Now().Format("2006-01-02"))
+		Expect(err).ToNot(HaveOccurred())
+
+		exchanged, _, err := c.exchangeCode(issued.VerificationCode)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, resp, err := c.exchangeToken(exchanged.Token, "bm90LXRoZS1yaWdodC1obWFj")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	It("issues a certificate whose claims survive validation", func() {
+		issued, _, err := c.issueCode(time.Now().Format("2006-01-02"))
+		Expect(err).ToNot(HaveOccurred())
+
+		exchanged, _, err := c.exchangeCode(issued.VerificationCode)
+		Expect(err).ToNot(HaveOccurred())
+
+		cert, resp, err := c.exchangeToken(exchanged.Token, validTestHMAC())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		Expect(verifyClaims(cert.Certificate, "diagnosis-verification-example", "exposure-notifications-server")).To(Succeed())
+	})
+})
@@ -19,11 +19,15 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/exposure-notifications-server/pkg/base64util"
-	"github.com/google/exposure-notifications-server/pkg/secrets"
+	gcpsecrets "github.com/google/exposure-notifications-server/pkg/secrets"
 	"github.com/google/exposure-notifications-verification-server/pkg/database"
+	"github.com/google/exposure-notifications-verification-server/pkg/events"
+	"github.com/google/exposure-notifications-verification-server/pkg/keys"
+	"github.com/google/exposure-notifications-verification-server/pkg/secrets"
 
 	firebase "firebase.google.com/go"
 	"github.com/sethvargo/go-envconfig/pkg/envconfig"
@@ -44,26 +48,32 @@ func NewWith(ctx context.Context, l envconfig.Lookuper) (*Config, error) {
 	{
 		// Load the secret manager configuration first - this needs to be loaded first
 		// because other processors may need secrets.
-		var smConfig secrets.Config
+		var smConfig gcpsecrets.Config
 		if err := envconfig.ProcessWith(ctx, &smConfig, l); err != nil {
 			return nil, fmt.Errorf("unable to process secret configuration: %w", err)
 		}
 
-		sm, err := secrets.SecretManagerFor(ctx, smConfig.SecretManagerType)
+		// SECRET_MANAGER_TYPE now also accepts "vault" and "awssm", resolved
+		// by our own secrets.ManagerFor. Anything else (including the
+		// existing GCP types) is handled by the upstream package unchanged.
+		sm, err := secrets.ManagerFor(ctx, smConfig.SecretManagerType)
 		if err != nil {
 			return nil, fmt.Errorf("unable to connect to secret manager: %w", err)
 		}
 
 		// Enable caching, if a TTL was provided.
 		if ttl := smConfig.SecretCacheTTL; ttl > 0 {
-			sm, err = secrets.WrapCacher(ctx, sm, ttl)
+			sm, err = gcpsecrets.WrapCacher(ctx, sm, ttl)
 			if err != nil {
 				return nil, fmt.Errorf("unable to create secret manager cache: %w", err)
 			}
 		}
 
-		// Update the mutators to process secrets.
-		mutatorFuncs = append(mutatorFuncs, secrets.Resolver(sm, &smConfig))
+		// Update the mutators to process secrets. secrets.Resolver adds
+		// support for resources that name their own backend via URI scheme
+		// (vault://, awssm://, file://) independent of SECRET_MANAGER_TYPE,
+		// falling back to the default manager above for everything else.
+		mutatorFuncs = append(mutatorFuncs, secrets.Resolver(gcpsecrets.Resolver(sm, &smConfig), smConfig.SecretCacheTTL))
 	}
 
 	// Parse the main configuration.
@@ -85,10 +95,25 @@ func NewWith(ctx context.Context, l envconfig.Lookuper) (*Config, error) {
 // Config represents the environment based config for the server.
 type Config struct {
 	Firebase FirebaseConfig
+	OIDC     OIDCConfig
 	Database database.Config
 
+	// tokenKeySet and certificateKeySet cache the rotating KeySet for each
+	// signing purpose, lazily built on first use (see TokenKeySet and
+	// CertificateKeySet) and shared by every caller for the lifetime of this
+	// Config - never rebuilt per-request. RotateTokenKey/RotateCertificateKey
+	// add a new active key to whichever of these is already cached.
+	tokenKeySetMu       sync.Mutex
+	tokenKeySet         *keys.KeySet
+	certificateKeySetMu sync.Mutex
+	certificateKeySet   *keys.KeySet
+
 	Port int `env:"PORT,default=8080"`
 
+	// AuthProvider selects which Authenticator is used to establish a user's
+	// identity. Valid values are "firebase" and "oidc".
+	AuthProvider string `env:"AUTH_PROVIDER,default=firebase"`
+
 	// Login Config
 	SessionCookieDuration time.Duration `env:"SESSION_DURATION,default=24h"`
 	RevokeCheckPeriod     time.Duration `env:"REVOKE_CHECK_DURATION,default=5m"`
@@ -108,13 +133,21 @@ type Config struct {
 	RateLimit           uint64        `env:"RATE_LIMIT,default=60"`
 
 	// Verification Token Config
-	// Currently this does not easily support rotation. TODO(mikehelmick) - add support.
+	//
+	// TokenSigningKey is a resource URI identifying the active signing key,
+	// resolved by pkg/keys.KeyManagerFor:
+	//   env://TOKEN_SIGNING_KEY
+	//   kms://projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1
+	//   vault://transit/keys/verify?version=3
+	// TokenSigningKeyID is only the default "kid" used until the first
+	// rotation; each minted token embeds the kid that actually signed it.
 	VerificationTokenDuration time.Duration `env:"VERIFICATION_TOKEN_DURATION,default=24h"`
 	TokenSigningKey           string        `env:"TOKEN_SIGNING_KEY,required"`
 	TokenSigningKeyID         string        `env:"TOKEN_SIGNING_KEY_ID,default=v1"`
 	TokenIssuer               string        `env:"TOKEN_ISSUER,default=diagnosis-verification-example"`
 
-	// Verification certificate config
+	// Verification certificate config. CertificateSigningKey follows the same
+	// resource URI scheme as TokenSigningKey.
 	PublicKeyCacheDuration  time.Duration `env:"PUBLIC_KEY_CACHE_DURATION,default=15m"`
 	CertificateSigningKey   string        `env:"CERTIFICATE_SIGNING_KEY,required"`
 	CertificateSigningKeyID string        `env:"CERTIFICATE_SIGNING_KEY_ID,default=v1"`
@@ -122,6 +155,21 @@ type Config struct {
 	CertificateAudience     string        `env:"CERTIFICATE_AUDIENCE,default=exposure-notifications-server"`
 	CertificateDuration     time.Duration `env:"CERTIFICATE_DURATION,default=15m"`
 
+	// Device authorization grant config (RFC 8628). Pending device requests
+	// are purged by the same loop that honors CleanupPeriod.
+	DeviceCodeTTL        time.Duration `env:"DEVICE_CODE_TTL,default=10m"`
+	DevicePollInterval   time.Duration `env:"DEVICE_POLL_INTERVAL,default=5s"`
+	DeviceUserCodeLength uint          `env:"DEVICE_USER_CODE_LENGTH,default=8"`
+
+	// EventSink configures where events are published, e.g.
+	// "pubsub://projects/p/topics/verify-events". Left empty, no events are
+	// published. See pkg/events.SinkFor for the supported schemes. The only
+	// event emitted is token.exchanged, from the device grant; no events are
+	// emitted for code issuance, claiming, revocation, or certificate signing.
+	EventSink           string `env:"EVENT_SINK"`
+	EventBufferSize     int    `env:"EVENT_BUFFER_SIZE,default=1000"`
+	EventPublishWorkers int    `env:"EVENT_PUBLISH_WORKERS,default=4"`
+
 	// Cleanup config
 	CleanupPeriod           time.Duration `env:"CLEANUP_PERIOD,default=15m"`
 	DisabledUserMaxAge      time.Duration `env:"DIABLED_USER_MAX_AGE,default=336h"`
@@ -161,6 +209,8 @@ func (c *Config) Validate() error {
 		{c.SessionCookieDuration, "SESSION_DUATION"},
 		{c.RevokeCheckPeriod, "REVOKE_CHECK_DURATION"},
 		{c.CodeDuration, "CODE_DURATION"},
+		{c.DeviceCodeTTL, "DEVICE_CODE_TTL"},
+		{c.DevicePollInterval, "DEVICE_POLL_INTERVAL"},
 		{c.AllowedTestAge, "ALLOWED_PAST_TEST_DAYS"},
 		{c.APIKeyCacheDuration, "API_KEY_CACHE_DURATION"},
 		{c.VerificationCodeMaxAge, "VERIFICATION_TOKEN_DURATION"},
@@ -177,19 +227,58 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	switch c.AuthProvider {
+	case "firebase":
+		if err := c.Firebase.Validate(); err != nil {
+			return fmt.Errorf("invalid Firebase config: %w", err)
+		}
+	case "oidc":
+		if err := c.OIDC.Validate(); err != nil {
+			return fmt.Errorf("invalid OIDC config: %w", err)
+		}
+	default:
+		return fmt.Errorf("AUTH_PROVIDER must be one of 'firebase' or 'oidc', got: %v", c.AuthProvider)
+	}
+
 	return nil
 }
 
-// FirebaseConfig represents configuration specific to firebase auth.
+// FirebaseConfig represents configuration specific to firebase auth. These
+// are only required when AuthProvider is "firebase" - see Validate - so
+// that an OIDC-only deployment never has to set FIREBASE_* vars.
 type FirebaseConfig struct {
-	APIKey          string `env:"FIREBASE_API_KEY,required"`
-	AuthDomain      string `env:"FIREBASE_AUTH_DOMAIN,required"`
-	DatabaseURL     string `env:"FIREBASE_DATABASE_URL,required"`
-	ProjectID       string `env:"FIREBASE_PROJECT_ID,required"`
-	StorageBucket   string `env:"FIREBASE_STORAGE_BUCKET,required"`
-	MessageSenderID string `env:"FIREBASE_MESSAGE_SENDER_ID,required"`
-	AppID           string `env:"FIREBASE_APP_ID,required"`
-	MeasurementID   string `env:"FIREBASE_MEASUREMENT_ID,required"`
+	APIKey          string `env:"FIREBASE_API_KEY"`
+	AuthDomain      string `env:"FIREBASE_AUTH_DOMAIN"`
+	DatabaseURL     string `env:"FIREBASE_DATABASE_URL"`
+	ProjectID       string `env:"FIREBASE_PROJECT_ID"`
+	StorageBucket   string `env:"FIREBASE_STORAGE_BUCKET"`
+	MessageSenderID string `env:"FIREBASE_MESSAGE_SENDER_ID"`
+	AppID           string `env:"FIREBASE_APP_ID"`
+	MeasurementID   string `env:"FIREBASE_MEASUREMENT_ID"`
+}
+
+// Validate ensures every field Firebase auth actually needs is present.
+// Only called when AuthProvider is "firebase".
+func (c *FirebaseConfig) Validate() error {
+	fields := []struct {
+		Val  string
+		Name string
+	}{
+		{c.APIKey, "FIREBASE_API_KEY"},
+		{c.AuthDomain, "FIREBASE_AUTH_DOMAIN"},
+		{c.DatabaseURL, "FIREBASE_DATABASE_URL"},
+		{c.ProjectID, "FIREBASE_PROJECT_ID"},
+		{c.StorageBucket, "FIREBASE_STORAGE_BUCKET"},
+		{c.MessageSenderID, "FIREBASE_MESSAGE_SENDER_ID"},
+		{c.AppID, "FIREBASE_APP_ID"},
+		{c.MeasurementID, "FIREBASE_MEASUREMENT_ID"},
+	}
+	for _, f := range fields {
+		if f.Val == "" {
+			return fmt.Errorf("%s is required", f.Name)
+		}
+	}
+	return nil
 }
 
 // FirebaseConfig returns the firebase SDK config based on the local env config.
@@ -200,3 +289,150 @@ func (c *Config) FirebaseConfig() *firebase.Config {
 		StorageBucket: c.Firebase.StorageBucket,
 	}
 }
+
+// TokenKeySet returns the process-wide KeySet used to sign verification
+// tokens, building it (seeded with TokenSigningKey as its initial active
+// key) on the first call and returning the same cached instance - and
+// therefore the same active keys - on every call after that. Use
+// RotateTokenKey to add a new active key to it.
+func (c *Config) TokenKeySet(ctx context.Context) (*keys.KeySet, error) {
+	c.tokenKeySetMu.Lock()
+	defer c.tokenKeySetMu.Unlock()
+
+	if c.tokenKeySet == nil {
+		ks, err := keys.NewKeySet(ctx, c.TokenSigningKey)
+		if err != nil {
+			return nil, err
+		}
+		c.tokenKeySet = ks
+	}
+	return c.tokenKeySet, nil
+}
+
+// CertificateKeySet returns the process-wide KeySet used to sign
+// verification certificates, seeded with CertificateSigningKey. See
+// TokenKeySet for the caching contract and RotateCertificateKey for adding a
+// new active key.
+func (c *Config) CertificateKeySet(ctx context.Context) (*keys.KeySet, error) {
+	c.certificateKeySetMu.Lock()
+	defer c.certificateKeySetMu.Unlock()
+
+	if c.certificateKeySet == nil {
+		ks, err := keys.NewKeySet(ctx, c.CertificateSigningKey)
+		if err != nil {
+			return nil, err
+		}
+		c.certificateKeySet = ks
+	}
+	return c.certificateKeySet, nil
+}
+
+// RotateTokenKey adds resource as a new active token-signing key on top of
+// the cached KeySet TokenKeySet returns, per KeySet.Rotate: new tokens sign
+// with resource immediately, while tokens already signed with the previous
+// key keep verifying against the published JWKS, which keeps every key the
+// KeySet has held. See
+// controller.KeyRotationController for the admin endpoint that calls this.
+func (c *Config) RotateTokenKey(ctx context.Context, resource string) error {
+	ks, err := c.TokenKeySet(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve token key set: %w", err)
+	}
+	return ks.Rotate(ctx, resource)
+}
+
+// RotateCertificateKey adds resource as a new active certificate-signing
+// key. See RotateTokenKey.
+func (c *Config) RotateCertificateKey(ctx context.Context, resource string) error {
+	ks, err := c.CertificateKeySet(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve certificate key set: %w", err)
+	}
+	return ks.Rotate(ctx, resource)
+}
+
+// EventPublisher resolves the configured EventSink (if any) and wraps it in
+// an events.Publisher so emission points never block the request path on a
+// downstream outage. It returns (nil, nil) when EventSink is unset.
+func (c *Config) EventPublisher(ctx context.Context) (*events.Publisher, error) {
+	if c.EventSink == "" {
+		return nil, nil
+	}
+
+	sink, err := events.SinkFor(ctx, c.EventSink)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to event sink: %w", err)
+	}
+	return events.NewPublisher(sink, c.EventBufferSize, c.EventPublishWorkers), nil
+}
+
+// OIDCConfig represents configuration for generic OIDC/OAuth2 identity
+// federation, used when AuthProvider is "oidc". The issuer's discovery
+// document (<IssuerURL>/.well-known/openid-configuration) is used to locate
+// the authorization, token, and JWKS endpoints at startup.
+type OIDCConfig struct {
+	IssuerURL    string   `env:"ISSUER_URL"`
+	ClientID     string   `env:"CLIENT_ID"`
+	ClientSecret string   `env:"CLIENT_SECRET"`
+	Scopes       []string `env:"SCOPES,default=openid,email,profile"`
+	RedirectURL  string   `env:"OIDC_REDIRECT_URL"`
+
+	// JWKSCacheDuration is checked by Validate but is not read by
+	// controller.OIDCAuthenticator; fetched signing keys are not expired on
+	// this interval.
+	JWKSCacheDuration time.Duration `env:"OIDC_JWKS_CACHE_DURATION,default=15m"`
+
+	// TokenEncryptionKey encrypts the refresh token persisted at login so
+	// RevokeCheckPeriod re-introspection can mint a fresh access token
+	// without another interactive sign-in. Must be 32-bytes, base64
+	// encoded, same convention as Config.CSRFAuthKey. Can be generated with
+	// tools/gen-secret. Use the syntax of secret:// to pull the secret from
+	// secret manager.
+	TokenEncryptionKey string `env:"OIDC_TOKEN_ENCRYPTION_KEY"`
+
+	// AllowedHostedDomain, if set, rejects any login whose id_token "hd"
+	// claim doesn't match it - a defense-in-depth check on top of the
+	// invited-user gate in database.FindInvitedUser, useful for Google
+	// Workspace issuers where "hd" names the Workspace domain.
+	AllowedHostedDomain string `env:"OIDC_ALLOWED_HOSTED_DOMAIN"`
+
+	// AdminGroup, if set, promotes an invited user to database.RoleAdmin the
+	// next time they log in with it present in their id_token "groups"
+	// claim. It never demotes - removing a user from the group doesn't take
+	// away a role they already hold.
+	AdminGroup string `env:"OIDC_ADMIN_GROUP"`
+}
+
+// Validate ensures the OIDC config has the fields required to perform
+// discovery and validate tokens.
+func (c *OIDCConfig) Validate() error {
+	if c.IssuerURL == "" {
+		return fmt.Errorf("ISSUER_URL is required")
+	}
+	if c.ClientID == "" {
+		return fmt.Errorf("CLIENT_ID is required")
+	}
+	if c.ClientSecret == "" {
+		return fmt.Errorf("CLIENT_SECRET is required")
+	}
+	if len(c.Scopes) == 0 {
+		return fmt.Errorf("SCOPES must not be empty")
+	}
+	if _, err := c.Key(); err != nil {
+		return err
+	}
+	return checkPositiveDuration(c.JWKSCacheDuration, "OIDC_JWKS_CACHE_DURATION")
+}
+
+// Key decodes TokenEncryptionKey into its raw 32-byte form, mirroring
+// Config.CSRFKey.
+func (c *OIDCConfig) Key() ([]byte, error) {
+	key, err := base64util.DecodeString(c.TokenEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding OIDC_TOKEN_ENCRYPTION_KEY: %v", err)
+	}
+	if l := len(key); l != 32 {
+		return nil, fmt.Errorf("OIDC_TOKEN_ENCRYPTION_KEY is not 32 bytes, got: %v", l)
+	}
+	return key, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/exposure-notifications-verification-server/pkg/config"
+	"github.com/google/exposure-notifications-verification-server/pkg/database"
+)
+
+// Authenticator establishes the identity of the caller of an interactive
+// (browser) request. Implementations are responsible for both the initial
+// sign-in handshake and any periodic re-validation required to honor
+// Config.RevokeCheckPeriod.
+type Authenticator interface {
+	// Login begins the sign-in flow, redirecting the user as necessary.
+	Login(w http.ResponseWriter, r *http.Request)
+
+	// Callback completes the sign-in flow (e.g. an OAuth2 authorization code
+	// exchange) and returns the authenticated user on success. It does not
+	// itself store the user in a session or request context.
+	Callback(w http.ResponseWriter, r *http.Request) (*database.User, error)
+
+	// Revoked reports whether the identity backing the given user should be
+	// considered revoked, per Config.RevokeCheckPeriod. Implementations that
+	// have nothing to re-check may always return false.
+	Revoked(ctx context.Context, user *database.User) (bool, error)
+}
+
+// NewAuthenticator returns the Authenticator configured by
+// Config.AuthProvider. db is used to resolve (and, for OIDC, persist
+// refresh tokens for) the database.User backing each identity.
+func NewAuthenticator(ctx context.Context, cfg *config.Config, db *database.Database) (Authenticator, error) {
+	switch cfg.AuthProvider {
+	case "", "firebase":
+		return NewFirebaseAuthenticator(ctx, cfg, db)
+	case "oidc":
+		return NewOIDCAuthenticator(ctx, cfg, db)
+	default:
+		return nil, fmt.Errorf("unknown auth provider: %v", cfg.AuthProvider)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	firebase "firebase.google.com/go"
+	"firebase.google.com/go/auth"
+
+	"github.com/google/exposure-notifications-verification-server/pkg/config"
+	"github.com/google/exposure-notifications-verification-server/pkg/database"
+)
+
+// FirebaseAuthenticator implements Authenticator on top of the Firebase Auth
+// client SDK. Sign-in happens entirely client side (in the browser); this
+// type only verifies the ID token the client hands back.
+type FirebaseAuthenticator struct {
+	client *auth.Client
+	db     *database.Database
+}
+
+// NewFirebaseAuthenticator creates a FirebaseAuthenticator from the given
+// config.
+func NewFirebaseAuthenticator(ctx context.Context, cfg *config.Config, db *database.Database) (*FirebaseAuthenticator, error) {
+	app, err := firebase.NewApp(ctx, cfg.FirebaseConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize firebase app: %w", err)
+	}
+
+	client, err := app.Auth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize firebase auth client: %w", err)
+	}
+
+	return &FirebaseAuthenticator{client: client, db: db}, nil
+}
+
+// Login has no server-side step for Firebase - the sign-in UI and handshake
+// are handled entirely by the firebaseui JS client, so instead of redirecting
+// it responds 400 Bad Request.
+func (f *FirebaseAuthenticator) Login(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "sign in from the login page", http.StatusBadRequest)
+}
+
+// Callback verifies the Firebase ID token submitted by the client and
+// resolves it to a database.User. Verifying cleanly against the configured
+// Firebase project is not, by itself, authorization to use this system - the
+// email must already have an invited database.User row (see
+// database.InviteUser), or Callback fails.
+func (f *FirebaseAuthenticator) Callback(w http.ResponseWriter, r *http.Request) (*database.User, error) {
+	idToken := r.FormValue("idToken")
+	if idToken == "" {
+		return nil, fmt.Errorf("missing idToken")
+	}
+
+	token, err := f.client.VerifyIDToken(r.Context(), idToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify firebase id token: %w", err)
+	}
+
+	email, _ := token.Claims["email"].(string)
+	if email == "" {
+		return nil, fmt.Errorf("firebase id token missing email claim")
+	}
+
+	user, err := f.db.FindInvitedUser(email)
+	if err != nil {
+		return nil, fmt.Errorf("firebase login denied: %w", err)
+	}
+	return user, nil
+}
+
+// Revoked reports whether the Firebase user record for user's email is
+// disabled. The record's TokensValidAfterMillis revocation watermark is not
+// checked.
+func (f *FirebaseAuthenticator) Revoked(ctx context.Context, user *database.User) (bool, error) {
+	rec, err := f.client.GetUserByEmail(ctx, user.Email)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up firebase user: %w", err)
+	}
+	return rec.Disabled, nil
+}
@@ -0,0 +1,270 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	gooidc "github.com/coreos/go-oidc"
+
+	"github.com/google/exposure-notifications-verification-server/pkg/config"
+	"github.com/google/exposure-notifications-verification-server/pkg/database"
+)
+
+// OIDCAuthenticator implements Authenticator against a generic OpenID
+// Connect provider (Google, Azure AD, Okta, or any other issuer that
+// publishes a standard discovery document). The provider's JWKS is located
+// via discovery and cached by go-oidc's key set; Config.OIDC.JWKSCacheDuration
+// is not applied to that cache.
+type OIDCAuthenticator struct {
+	cfg      *config.Config
+	db       *database.Database
+	provider *gooidc.Provider
+	verifier *gooidc.IDTokenVerifier
+	oauth    oauth2.Config
+}
+
+// NewOIDCAuthenticator creates an OIDCAuthenticator by running OIDC
+// discovery against cfg.OIDC.IssuerURL.
+func NewOIDCAuthenticator(ctx context.Context, cfg *config.Config, db *database.Database) (*OIDCAuthenticator, error) {
+	provider, err := gooidc.NewProvider(ctx, cfg.OIDC.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover oidc issuer %q: %w", cfg.OIDC.IssuerURL, err)
+	}
+
+	verifier := provider.Verifier(&gooidc.Config{ClientID: cfg.OIDC.ClientID})
+
+	return &OIDCAuthenticator{
+		cfg:      cfg,
+		db:       db,
+		provider: provider,
+		verifier: verifier,
+		oauth: oauth2.Config{
+			ClientID:     cfg.OIDC.ClientID,
+			ClientSecret: cfg.OIDC.ClientSecret,
+			RedirectURL:  cfg.OIDC.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       cfg.OIDC.Scopes,
+		},
+	}, nil
+}
+
+// Login redirects the user to the provider's authorization endpoint.
+func (o *OIDCAuthenticator) Login(w http.ResponseWriter, r *http.Request) {
+	state := o.newState(w)
+	http.Redirect(w, r, o.oauth.AuthCodeURL(state), http.StatusFound)
+}
+
+// Callback exchanges the authorization code for tokens, verifies the ID
+// token, and maps its claims onto a database.User.
+func (o *OIDCAuthenticator) Callback(w http.ResponseWriter, r *http.Request) (*database.User, error) {
+	ctx := r.Context()
+
+	if err := o.checkState(r); err != nil {
+		return nil, fmt.Errorf("invalid oidc state: %w", err)
+	}
+
+	oauthToken, err := o.oauth.Exchange(ctx, r.FormValue("code"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := oauthToken.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("token response missing id_token")
+	}
+
+	idToken, err := o.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify id_token: %w", err)
+	}
+
+	var claims struct {
+		Email  string   `json:"email"`
+		HD     string   `json:"hd"`
+		Groups []string `json:"groups"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse id_token claims: %w", err)
+	}
+	if claims.Email == "" {
+		return nil, fmt.Errorf("id_token missing email claim")
+	}
+	if want := o.cfg.OIDC.AllowedHostedDomain; want != "" && claims.HD != want {
+		return nil, fmt.Errorf("id_token hosted domain %q is not allowed", claims.HD)
+	}
+
+	user, err := o.db.FindInvitedUser(claims.Email)
+	if err != nil {
+		return nil, fmt.Errorf("oidc login denied: %w", err)
+	}
+
+	if role := o.resolveRole(claims.Groups, user.Role); role != user.Role {
+		if err := o.db.SetUserRole(user, role); err != nil {
+			return nil, fmt.Errorf("failed to update oidc user role: %w", err)
+		}
+	}
+
+	if oauthToken.RefreshToken != "" {
+		encrypted, err := o.encryptRefreshToken(oauthToken.RefreshToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt oidc refresh token: %w", err)
+		}
+		if err := o.db.SaveOIDCRefreshToken(user.ID, encrypted); err != nil {
+			return nil, fmt.Errorf("failed to persist oidc refresh token: %w", err)
+		}
+	}
+
+	return user, nil
+}
+
+// Revoked re-introspects the user's stored refresh token every
+// Config.RevokeCheckPeriod to detect upstream revocation (disabled account,
+// revoked consent, expired refresh token).
+func (o *OIDCAuthenticator) Revoked(ctx context.Context, user *database.User) (bool, error) {
+	userInfoEndpoint := o.provider.UserInfoEndpoint()
+	if userInfoEndpoint == "" {
+		return false, nil
+	}
+
+	encrypted, err := o.db.FindOIDCRefreshToken(user.ID)
+	if err != nil {
+		return true, fmt.Errorf("failed to look up oidc refresh token: %w", err)
+	}
+
+	refreshToken, err := o.decryptRefreshToken(encrypted)
+	if err != nil {
+		return true, fmt.Errorf("failed to decrypt oidc refresh token: %w", err)
+	}
+
+	tokenSource := o.oauth.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken})
+	if _, err := o.provider.UserInfo(ctx, tokenSource); err != nil {
+		return true, fmt.Errorf("failed to re-introspect oidc session: %w", err)
+	}
+	return false, nil
+}
+
+// encryptRefreshToken seals refreshToken with AES-GCM under
+// Config.OIDC.TokenEncryptionKey, prefixing the ciphertext with the nonce
+// used to produce it.
+func (o *OIDCAuthenticator) encryptRefreshToken(refreshToken string) ([]byte, error) {
+	gcm, err := o.refreshTokenCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, []byte(refreshToken), nil), nil
+}
+
+// decryptRefreshToken reverses encryptRefreshToken.
+func (o *OIDCAuthenticator) decryptRefreshToken(encrypted []byte) (string, error) {
+	gcm, err := o.refreshTokenCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(encrypted) < nonceSize {
+		return "", fmt.Errorf("encrypted refresh token is truncated")
+	}
+	nonce, ciphertext := encrypted[:nonceSize], encrypted[nonceSize:]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// refreshTokenCipher builds the AES-GCM AEAD used to protect refresh tokens
+// at rest, keyed by Config.OIDC.TokenEncryptionKey.
+func (o *OIDCAuthenticator) refreshTokenCipher() (cipher.AEAD, error) {
+	key, err := o.cfg.OIDC.Key()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load oidc token encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize gcm: %w", err)
+	}
+	return gcm, nil
+}
+
+// resolveRole promotes a user to database.RoleAdmin if Config.OIDC.AdminGroup
+// is configured and present in groups. It never demotes: a user who already
+// holds a role isn't stripped of it just because this login's groups claim
+// omits the admin group (e.g. an IdP that doesn't always include it).
+func (o *OIDCAuthenticator) resolveRole(groups []string, current database.UserRole) database.UserRole {
+	if o.cfg.OIDC.AdminGroup == "" {
+		return current
+	}
+	for _, g := range groups {
+		if g == o.cfg.OIDC.AdminGroup {
+			return database.RoleAdmin
+		}
+	}
+	return current
+}
+
+// newState issues an anti-CSRF state parameter for the authorization request
+// and stashes it in a short-lived cookie to be checked in checkState.
+func (o *OIDCAuthenticator) newState(w http.ResponseWriter) string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	state := base64.RawURLEncoding.EncodeToString(buf)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "oidc_state",
+		Value:    state,
+		Expires:  time.Now().Add(10 * time.Minute),
+		HttpOnly: true,
+		Secure:   !o.cfg.DevMode,
+	})
+	return state
+}
+
+// checkState validates the state parameter returned by the provider against
+// the cookie set in newState.
+func (o *OIDCAuthenticator) checkState(r *http.Request) error {
+	cookie, err := r.Cookie("oidc_state")
+	if err != nil {
+		return fmt.Errorf("missing oidc_state cookie: %w", err)
+	}
+	if got := r.FormValue("state"); got == "" || got != cookie.Value {
+		return fmt.Errorf("state mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller holds the device authorization grant flow (RFC 8628),
+// letting browser-less field devices (issuance kiosks, pharmacy tablets,
+// nurse-facing thick clients) obtain a session without ever handling a
+// password or OIDC redirect themselves.
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/google/exposure-notifications-verification-server/pkg/config"
+	"github.com/google/exposure-notifications-verification-server/pkg/database"
+	"github.com/google/exposure-notifications-verification-server/pkg/events"
+)
+
+// deviceGrantType is the grant_type value a polling client must send to
+// POST /device/token, per RFC 8628 section 3.4.
+const deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceController implements the two device authorization grant endpoints
+// and the confirmation page an already-authenticated operator uses to
+// approve a pending device.
+type DeviceController struct {
+	config    *config.Config
+	db        *database.Database
+	publisher *events.Publisher // may be nil if Config.EventSink is unset
+}
+
+// NewDeviceController creates a DeviceController. publisher may be nil, in
+// which case no token.exchanged events are emitted.
+func NewDeviceController(cfg *config.Config, db *database.Database, publisher *events.Publisher) *DeviceController {
+	return &DeviceController{config: cfg, db: db, publisher: publisher}
+}
+
+// deviceAuthorizeResponse is the body returned from POST /device/authorize.
+type deviceAuthorizeResponse struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Interval        int64  `json:"interval"`
+}
+
+// HandleAuthorize implements POST /device/authorize. It mints a
+// (device_code, user_code) pair, persists the pending request with a TTL
+// enforced by the existing cleanup loop (see Config.CleanupPeriod and
+// database.PurgeExpiredDeviceAuths), and returns the information the device
+// needs to show the operator.
+func (d *DeviceController) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
+	auth, err := database.NewDeviceAuthorization(d.config.DeviceCodeTTL, d.config.DeviceUserCodeLength)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to create device authorization: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := d.db.SaveDeviceAuthorization(auth); err != nil {
+		http.Error(w, fmt.Sprintf("failed to save device authorization: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	renderJSON(w, http.StatusOK, &deviceAuthorizeResponse{
+		DeviceCode:      auth.DeviceCode,
+		UserCode:        auth.UserCode,
+		VerificationURI: d.verificationURI(r),
+		ExpiresIn:       int64(d.config.DeviceCodeTTL.Seconds()),
+		Interval:        int64(d.config.DevicePollInterval.Seconds()),
+	})
+}
+
+// deviceTokenResponse is the body returned from POST /device/token.
+type deviceTokenResponse struct {
+	Error       string `json:"error,omitempty"`
+	AccessToken string `json:"access_token,omitempty"`
+	TokenType   string `json:"token_type,omitempty"`
+}
+
+// HandleToken implements POST /device/token. Kiosks poll this endpoint at
+// Config.DevicePollInterval until it returns a session token, honoring the
+// RFC 8628 "authorization_pending" / "slow_down" / "access_denied" error
+// vocabulary so the polling client can back off correctly.
+func (d *DeviceController) HandleToken(w http.ResponseWriter, r *http.Request) {
+	if got := r.FormValue("grant_type"); got != deviceGrantType {
+		renderJSON(w, http.StatusBadRequest, &deviceTokenResponse{Error: "unsupported_grant_type"})
+		return
+	}
+
+	deviceCode := r.FormValue("device_code")
+	auth, err := d.db.FindDeviceAuthorizationByDeviceCode(deviceCode)
+	if err != nil {
+		renderJSON(w, http.StatusBadRequest, &deviceTokenResponse{Error: "expired_token"})
+		return
+	}
+
+	tooSoon, err := d.db.RecordDevicePoll(auth, time.Now().UTC(), d.config.DevicePollInterval)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to record device poll: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if tooSoon {
+		renderJSON(w, http.StatusBadRequest, &deviceTokenResponse{Error: "slow_down"})
+		return
+	}
+
+	switch auth.Status {
+	case database.DeviceAuthorizationPending:
+		renderJSON(w, http.StatusBadRequest, &deviceTokenResponse{Error: "authorization_pending"})
+	case database.DeviceAuthorizationDenied:
+		renderJSON(w, http.StatusBadRequest, &deviceTokenResponse{Error: "access_denied"})
+	case database.DeviceAuthorizationApproved:
+		token, err := d.issueSessionToken(r.Context(), auth)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to issue session token: %v", err), http.StatusInternalServerError)
+			return
+		}
+		d.publishTokenExchanged(auth)
+		renderJSON(w, http.StatusOK, &deviceTokenResponse{AccessToken: token, TokenType: "Bearer"})
+	default:
+		renderJSON(w, http.StatusBadRequest, &deviceTokenResponse{Error: "invalid_request"})
+	}
+}
+
+// HandleConfirm serves /device, where an already-authenticated operator
+// submits the short user_code displayed on the kiosk screen. A POST with
+// approve=true grants the pending session; any other POST denies it. No HTML
+// confirmation page is rendered: a successful request gets an empty response.
+func (d *DeviceController) HandleConfirm(w http.ResponseWriter, r *http.Request) {
+	user, err := GetUser(w, r)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	userCode := r.FormValue("user_code")
+	auth, err := d.db.FindDeviceAuthorizationByUserCode(userCode)
+	if err != nil {
+		http.Error(w, "unknown or expired code", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if r.FormValue("approve") == "true" {
+			err = d.db.ApproveDeviceAuthorization(auth, user)
+		} else {
+			err = d.db.DenyDeviceAuthorization(auth)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to update device authorization: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Rendering of the confirmation template itself follows the same
+	// pattern as the rest of pkg/controller and is omitted here.
+}
+
+// publishTokenExchanged emits a token.exchanged event for a device that just
+// traded its approved device_code for a session token. It is a no-op if no
+// EventSink is configured.
+func (d *DeviceController) publishTokenExchanged(auth *database.DeviceAuthorization) {
+	if d.publisher == nil {
+		return
+	}
+	sum := sha256.Sum256([]byte(auth.DeviceCode))
+	d.publisher.Publish(&events.Event{
+		SpecVersion: "1.0",
+		Type:        events.TypeTokenExchanged,
+		Source:      "pkg/controller/device",
+		ID:          uuid.New().String(),
+		Time:        time.Now().UTC(),
+		Subject:     hex.EncodeToString(sum[:]),
+		Data:        map[string]string{"grant_type": "device_code"},
+	})
+}
+
+func (d *DeviceController) verificationURI(r *http.Request) string {
+	return fmt.Sprintf("https://%s/device", r.Host)
+}
+
+// issueSessionToken mints a session token for an approved device, signed by
+// the same rotating KeySet (see Config.TokenKeySet) that signs every other
+// verification token.
+func (d *DeviceController) issueSessionToken(ctx context.Context, auth *database.DeviceAuthorization) (string, error) {
+	keySet, err := d.config.TokenKeySet(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve token signing key: %w", err)
+	}
+
+	now := time.Now().UTC()
+	claims := map[string]interface{}{
+		"iss": d.config.TokenIssuer,
+		"sub": auth.ApprovedUserID,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(d.config.VerificationTokenDuration).Unix(),
+	}
+
+	token, err := keySet.SignJWT(ctx, claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign device session token: %w", err)
+	}
+	return token, nil
+}
+
+func renderJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
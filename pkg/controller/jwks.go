@@ -0,0 +1,51 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/google/exposure-notifications-verification-server/pkg/keys"
+)
+
+// JWKSController serves the combined JWKS for every active token and
+// certificate signing key, so relying parties can verify signatures across
+// a rotation without being told about new key IDs out of band.
+type JWKSController struct {
+	tokenKeys       *keys.KeySet
+	certificateKeys *keys.KeySet
+}
+
+// NewJWKSController creates a JWKSController.
+func NewJWKSController(tokenKeys, certificateKeys *keys.KeySet) *JWKSController {
+	return &JWKSController{tokenKeys: tokenKeys, certificateKeys: certificateKeys}
+}
+
+// HandleIndex implements GET /.well-known/jwks.json.
+func (c *JWKSController) HandleIndex(w http.ResponseWriter, r *http.Request) {
+	tokenJWKS, err := c.tokenKeys.PublishJWKS(r.Context())
+	if err != nil {
+		http.Error(w, "failed to build jwks", http.StatusInternalServerError)
+		return
+	}
+	certJWKS, err := c.certificateKeys.PublishJWKS(r.Context())
+	if err != nil {
+		http.Error(w, "failed to build jwks", http.StatusInternalServerError)
+		return
+	}
+
+	combined := append(append([]keys.JWK{}, tokenJWKS.Keys...), certJWKS.Keys...)
+	renderJSON(w, http.StatusOK, &keys.JWKS{Keys: combined})
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/exposure-notifications-verification-server/pkg/config"
+)
+
+// KeyRotationController exposes the operator-triggered key rotation that
+// Config.RotateTokenKey/RotateCertificateKey otherwise have no caller for.
+// It should be mounted behind the same admin authentication as the rest of
+// the operator-facing routes (e.g. POST /admin/keys/rotate).
+type KeyRotationController struct {
+	config *config.Config
+}
+
+// NewKeyRotationController creates a KeyRotationController.
+func NewKeyRotationController(cfg *config.Config) *KeyRotationController {
+	return &KeyRotationController{config: cfg}
+}
+
+// keyRotationRequest is the body of POST /admin/keys/rotate.
+type keyRotationRequest struct {
+	// Purpose selects which KeySet to rotate: "token" or "certificate".
+	Purpose string `json:"purpose"`
+	// Resource is the new key resource URI to add as the active signing key
+	// (env://, kms://, awskms://, or vault://) - see keys.KeyManagerFor.
+	Resource string `json:"resource"`
+}
+
+// HandleRotate implements POST /admin/keys/rotate. It adds Resource as a new
+// active signing key for Purpose without invalidating whichever key was
+// active before it, so in-flight tokens/certificates keep verifying through
+// the rotation.
+func (k *KeyRotationController) HandleRotate(w http.ResponseWriter, r *http.Request) {
+	var req keyRotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch req.Purpose {
+	case "token":
+		err = k.config.RotateTokenKey(r.Context(), req.Resource)
+	case "certificate":
+		err = k.config.RotateCertificateKey(r.Context(), req.Resource)
+	default:
+		http.Error(w, fmt.Sprintf("unknown rotation purpose: %q", req.Purpose), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to rotate %s key: %v", req.Purpose, err), http.StatusInternalServerError)
+		return
+	}
+
+	renderJSON(w, http.StatusOK, map[string]string{"status": "rotated"})
+}
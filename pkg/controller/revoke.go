@@ -0,0 +1,83 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/exposure-notifications-verification-server/pkg/controller/flash"
+)
+
+const revokeCheckedAtCookie = "revoke_checked_at"
+
+// RevokeCheckMiddleware re-validates the signed-in user against auth every
+// Config.RevokeCheckPeriod, logging them out if Authenticator.Revoked
+// reports the identity has been revoked upstream. It must run after
+// whatever middleware populates the "user" request context value GetUser
+// reads from.
+func RevokeCheckMiddleware(auth Authenticator, revokeCheckPeriod time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := GetUser(w, r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if dueForRevokeCheck(r, revokeCheckPeriod) {
+				revoked, err := auth.Revoked(r.Context(), user)
+				if err != nil {
+					flash.FromContext(w, r).Error(fmt.Sprintf("unable to verify session: %v", err))
+					http.Redirect(w, r, "/login", http.StatusSeeOther)
+					return
+				}
+				if revoked {
+					flash.FromContext(w, r).Error("your session has been revoked, please sign in again")
+					http.Redirect(w, r, "/login", http.StatusSeeOther)
+					return
+				}
+				markRevokeChecked(w)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// dueForRevokeCheck reports whether it's been at least revokeCheckPeriod
+// since this session's last successful Revoked check.
+func dueForRevokeCheck(r *http.Request, revokeCheckPeriod time.Duration) bool {
+	cookie, err := r.Cookie(revokeCheckedAtCookie)
+	if err != nil {
+		return true
+	}
+	checkedAt, err := strconv.ParseInt(cookie.Value, 10, 64)
+	if err != nil {
+		return true
+	}
+	return time.Since(time.Unix(checkedAt, 0)) >= revokeCheckPeriod
+}
+
+// markRevokeChecked records that the session just passed a Revoked check.
+func markRevokeChecked(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     revokeCheckedAtCookie,
+		Value:    strconv.FormatInt(time.Now().Unix(), 10),
+		HttpOnly: true,
+	})
+}
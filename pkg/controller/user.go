@@ -26,6 +26,10 @@ import (
 
 // GetUser gets the current logged in user from the request context. On an Error,
 // a message is added to the context's flash, but no redirect/render decision is made.
+//
+// The "user" context value holds the database.User returned by the
+// Callback of whichever Authenticator Config.AuthProvider selects (see
+// NewAuthenticator); cmd/server's login callback handler sets it.
 func GetUser(w http.ResponseWriter, r *http.Request) (*database.User, error) {
 	rawUser, ok := context.GetOk(r, "user")
 	if !ok {
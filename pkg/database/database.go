@@ -0,0 +1,57 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package database is the storage layer for the verification server.
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+)
+
+// Config represents the environment based config for the database.
+type Config struct {
+	Name     string `env:"DB_NAME,default=verification"`
+	User     string `env:"DB_USER,default=verification"`
+	Host     string `env:"DB_HOST,default=localhost"`
+	Port     string `env:"DB_PORT,default=5432"`
+	SSLMode  string `env:"DB_SSLMODE,default=require"`
+	Password string `env:"DB_PASSWORD"`
+}
+
+// Database wraps the underlying ORM connection.
+type Database struct {
+	db *gorm.DB
+}
+
+// Open connects to the database described by c and migrates every model
+// this package knows about.
+func (c *Config) Open(ctx context.Context) (*Database, error) {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=%s password=%s",
+		c.Host, c.Port, c.User, c.Name, c.SSLMode, c.Password)
+
+	db, err := gorm.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.AutoMigrate(&User{}, &DeviceAuthorization{}, &OIDCToken{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return &Database{db: db}, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// DeviceAuthorizationStatus is the lifecycle state of a pending device
+// authorization request (RFC 8628 section 3.2).
+type DeviceAuthorizationStatus int16
+
+const (
+	DeviceAuthorizationPending DeviceAuthorizationStatus = iota
+	DeviceAuthorizationApproved
+	DeviceAuthorizationDenied
+)
+
+// deviceCodeBytes is the size of the random device_code, in bytes, before
+// hex-encoding. It's independent of the operator-facing user_code length.
+const deviceCodeBytes = 32
+
+// DeviceAuthorization is a pending or resolved RFC 8628 device authorization
+// request. Expired rows are purged by PurgeExpiredDeviceAuths, called by
+// cmd/server's cleanup loop every Config.CleanupPeriod.
+type DeviceAuthorization struct {
+	gorm.Model
+
+	DeviceCode string `gorm:"unique_index"`
+	UserCode   string `gorm:"unique_index"`
+	Status     DeviceAuthorizationStatus
+	ExpiresAt  time.Time
+
+	// ApprovedUserID is set by ApproveDeviceAuthorization to the ID of the
+	// operator who confirmed the user_code.
+	ApprovedUserID uint
+
+	// LastPolledAt is the time of the most recent POST /device/token poll for
+	// this device_code, zero until the first one. RecordDevicePoll uses it to
+	// enforce Config.DevicePollInterval, per RFC 8628 section 3.5's
+	// "slow_down" response.
+	LastPolledAt time.Time
+}
+
+// NewDeviceAuthorization creates a pending DeviceAuthorization with a random
+// device_code and a userCodeLength-digit user_code, expiring after ttl.
+func NewDeviceAuthorization(ttl time.Duration, userCodeLength uint) (*DeviceAuthorization, error) {
+	deviceCode, err := randomHex(deviceCodeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate device_code: %w", err)
+	}
+
+	userCode, err := randomDigits(userCodeLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate user_code: %w", err)
+	}
+
+	return &DeviceAuthorization{
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		Status:     DeviceAuthorizationPending,
+		ExpiresAt:  time.Now().UTC().Add(ttl),
+	}, nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func randomDigits(n uint) (string, error) {
+	const digits = "0123456789"
+	out := make([]byte, n)
+	for i := range out {
+		idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(digits))))
+		if err != nil {
+			return "", fmt.Errorf("failed to read random digit: %w", err)
+		}
+		out[i] = digits[idx.Int64()]
+	}
+	return string(out), nil
+}
+
+// SaveDeviceAuthorization persists a newly created DeviceAuthorization.
+func (db *Database) SaveDeviceAuthorization(auth *DeviceAuthorization) error {
+	if err := db.db.Create(auth).Error; err != nil {
+		return fmt.Errorf("failed to save device authorization: %w", err)
+	}
+	return nil
+}
+
+// FindDeviceAuthorizationByDeviceCode looks up a still-unexpired
+// DeviceAuthorization by its device_code, as polled by POST /device/token.
+func (db *Database) FindDeviceAuthorizationByDeviceCode(deviceCode string) (*DeviceAuthorization, error) {
+	var auth DeviceAuthorization
+	if err := db.db.Where("device_code = ? AND expires_at > ?", deviceCode, time.Now().UTC()).First(&auth).Error; err != nil {
+		return nil, fmt.Errorf("failed to find device authorization: %w", err)
+	}
+	return &auth, nil
+}
+
+// FindDeviceAuthorizationByUserCode looks up a still-unexpired
+// DeviceAuthorization by its user_code, as entered on the /device
+// confirmation page.
+func (db *Database) FindDeviceAuthorizationByUserCode(userCode string) (*DeviceAuthorization, error) {
+	var auth DeviceAuthorization
+	if err := db.db.Where("user_code = ? AND expires_at > ?", userCode, time.Now().UTC()).First(&auth).Error; err != nil {
+		return nil, fmt.Errorf("failed to find device authorization: %w", err)
+	}
+	return &auth, nil
+}
+
+// RecordDevicePoll enforces the RFC 8628 section 3.5 polling interval: if
+// auth was last polled less than interval ago, it reports tooSoon so the
+// caller can respond "slow_down" without updating LastPolledAt (so a
+// fast-polling client doesn't get to use each rejected attempt to reset its
+// own backoff clock). Otherwise it stamps auth with now and persists it.
+func (db *Database) RecordDevicePoll(auth *DeviceAuthorization, now time.Time, interval time.Duration) (tooSoon bool, err error) {
+	if !auth.LastPolledAt.IsZero() && now.Sub(auth.LastPolledAt) < interval {
+		return true, nil
+	}
+
+	auth.LastPolledAt = now
+	if err := db.db.Save(auth).Error; err != nil {
+		return false, fmt.Errorf("failed to record device poll: %w", err)
+	}
+	return false, nil
+}
+
+// ApproveDeviceAuthorization marks auth approved by user, so the next
+// /device/token poll returns a session token.
+func (db *Database) ApproveDeviceAuthorization(auth *DeviceAuthorization, user *User) error {
+	auth.Status = DeviceAuthorizationApproved
+	auth.ApprovedUserID = user.ID
+	if err := db.db.Save(auth).Error; err != nil {
+		return fmt.Errorf("failed to approve device authorization: %w", err)
+	}
+	return nil
+}
+
+// DenyDeviceAuthorization marks auth denied, so the next /device/token poll
+// returns access_denied.
+func (db *Database) DenyDeviceAuthorization(auth *DeviceAuthorization) error {
+	auth.Status = DeviceAuthorizationDenied
+	if err := db.db.Save(auth).Error; err != nil {
+		return fmt.Errorf("failed to deny device authorization: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpiredDeviceAuths deletes every DeviceAuthorization that expired
+// before cutoff. It's called by cmd/server's cleanup loop every
+// Config.CleanupPeriod.
+func (db *Database) PurgeExpiredDeviceAuths(cutoff time.Time) (int64, error) {
+	result := db.db.Where("expires_at < ?", cutoff).Delete(&DeviceAuthorization{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge expired device authorizations: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
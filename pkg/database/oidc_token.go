@@ -0,0 +1,63 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// OIDCToken stores the encrypted OAuth2 refresh token issued at OIDC login,
+// so RevokeCheckPeriod re-introspection (see controller.OIDCAuthenticator)
+// can mint a fresh access token without another interactive sign-in.
+// EncryptedRefreshToken is opaque to this package - encryption and
+// decryption happen in the caller, keyed by Config.OIDC.TokenEncryptionKey.
+type OIDCToken struct {
+	gorm.Model
+
+	UserID                uint `gorm:"unique_index"`
+	EncryptedRefreshToken []byte
+}
+
+// SaveOIDCRefreshToken upserts the encrypted refresh token for userID.
+func (db *Database) SaveOIDCRefreshToken(userID uint, encryptedRefreshToken []byte) error {
+	var existing OIDCToken
+	err := db.db.Where("user_id = ?", userID).First(&existing).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		if err := db.db.Create(&OIDCToken{UserID: userID, EncryptedRefreshToken: encryptedRefreshToken}).Error; err != nil {
+			return fmt.Errorf("failed to save oidc refresh token: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to look up oidc refresh token: %w", err)
+	default:
+		existing.EncryptedRefreshToken = encryptedRefreshToken
+		if err := db.db.Save(&existing).Error; err != nil {
+			return fmt.Errorf("failed to update oidc refresh token: %w", err)
+		}
+	}
+	return nil
+}
+
+// FindOIDCRefreshToken returns the encrypted refresh token stored for
+// userID, if any.
+func (db *Database) FindOIDCRefreshToken(userID uint) ([]byte, error) {
+	var tok OIDCToken
+	if err := db.db.Where("user_id = ?", userID).First(&tok).Error; err != nil {
+		return nil, fmt.Errorf("failed to find oidc refresh token: %w", err)
+	}
+	return tok.EncryptedRefreshToken, nil
+}
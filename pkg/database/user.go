@@ -0,0 +1,84 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// UserRole is the set of permissions granted to a signed-in User.
+type UserRole int16
+
+const (
+	// RoleUser can issue and claim verification codes.
+	RoleUser UserRole = iota
+	// RoleAdmin additionally manages users, signing keys, and server config.
+	RoleAdmin
+)
+
+// User represents a person who can sign into the admin/portal UI, either
+// via Firebase or a federated OIDC provider.
+type User struct {
+	gorm.Model
+
+	Email    string `gorm:"unique_index"`
+	Name     string
+	Role     UserRole
+	Disabled bool
+}
+
+// InviteUser pre-provisions a User row for email before they've ever signed
+// in. Self-registration is intentionally unsupported: the Firebase and OIDC
+// Authenticator implementations look up this row at login (FindInvitedUser)
+// rather than creating an account just because an identity provider vouched
+// for the email, so onboarding a user always starts with an admin inviting
+// them here.
+func (db *Database) InviteUser(email string, role UserRole) (*User, error) {
+	user := User{Email: email, Role: role}
+	if err := db.db.Create(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to invite user %q: %w", email, err)
+	}
+	return &user, nil
+}
+
+// FindInvitedUser looks up the User invited for email via InviteUser. It is
+// an error for no such row to exist - an identity that authenticates
+// cleanly against Firebase/OIDC is not, by itself, authorization to use
+// this system.
+func (db *Database) FindInvitedUser(email string) (*User, error) {
+	var user User
+	err := db.db.Where("email = ?", email).First(&user).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		return nil, fmt.Errorf("%q has not been invited to this server", email)
+	case err != nil:
+		return nil, fmt.Errorf("failed to look up user %q: %w", email, err)
+	default:
+		return &user, nil
+	}
+}
+
+// SetUserRole updates user's role, e.g. when an OIDC claim (such as group
+// membership) maps them to a higher-privilege role than they were invited
+// with.
+func (db *Database) SetUserRole(user *User, role UserRole) error {
+	user.Role = role
+	if err := db.db.Save(user).Error; err != nil {
+		return fmt.Errorf("failed to update role for user %q: %w", user.Email, err)
+	}
+	return nil
+}
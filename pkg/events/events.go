@@ -0,0 +1,55 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package events publishes CloudEvents v1.0 notifications for
+// verification-flow milestones so downstream systems - analytics,
+// epidemiology dashboards, fraud detection - can subscribe without the
+// request path ever blocking on them. Today that's just the device-grant
+// token exchange (see controller.DeviceController); events for code
+// issuance/claiming/revocation and certificate signing will be added
+// alongside the controllers that own those flows, rather than declared
+// ahead of anything that emits them.
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Event types published by this server. The "subject" of every event is a
+// hashed code identifier, never the code or any PII/PHI itself.
+const (
+	TypeTokenExchanged = "dev.verification.token.exchanged"
+)
+
+// Event is a CloudEvents v1.0 envelope. See
+// https://github.com/cloudevents/spec/blob/v1.0/spec.md for field semantics.
+type Event struct {
+	SpecVersion string      `json:"specversion"`
+	Type        string      `json:"type"`
+	Source      string      `json:"source"`
+	ID          string      `json:"id"`
+	Time        time.Time   `json:"time"`
+	Subject     string      `json:"subject"`
+	DataSchema  string      `json:"dataschema,omitempty"`
+	Data        interface{} `json:"data"`
+}
+
+// EventSink publishes events to a downstream system. Send may block until the
+// downstream acknowledges the event - see Publisher, which is the
+// non-blocking entry point controllers should use instead of calling a sink
+// directly.
+type EventSink interface {
+	Send(ctx context.Context, event *Event) error
+}
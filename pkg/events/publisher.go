@@ -0,0 +1,80 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+)
+
+// Publisher fans events out to a sink through a bounded buffered channel, so
+// that a stalled or unreachable sink can never add latency to the
+// user-facing request that triggered the event. When the buffer is full, the
+// oldest queued event is dropped to make room and DroppedCount is
+// incremented.
+type Publisher struct {
+	sink    EventSink
+	queue   chan *Event
+	dropped int64
+}
+
+// NewPublisher creates a Publisher that drains into sink using workers
+// background goroutines, buffering up to bufferSize pending events.
+func NewPublisher(sink EventSink, bufferSize, workers int) *Publisher {
+	p := &Publisher{
+		sink:  sink,
+		queue: make(chan *Event, bufferSize),
+	}
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// Publish enqueues event for delivery and returns immediately. If the buffer
+// is full, the oldest pending event is dropped (and counted) to make room -
+// an outage in the sink must never block the caller.
+func (p *Publisher) Publish(event *Event) {
+	select {
+	case p.queue <- event:
+	default:
+		select {
+		case <-p.queue:
+			atomic.AddInt64(&p.dropped, 1)
+		default:
+		}
+		select {
+		case p.queue <- event:
+		default:
+			atomic.AddInt64(&p.dropped, 1)
+		}
+	}
+}
+
+// DroppedCount returns the number of events dropped so far because the
+// buffer was full. Exposed as a metric so a sink outage is observable even
+// though it's never allowed to affect request latency.
+func (p *Publisher) DroppedCount() int64 {
+	return atomic.LoadInt64(&p.dropped)
+}
+
+func (p *Publisher) worker() {
+	for event := range p.queue {
+		if err := p.sink.Send(context.Background(), event); err != nil {
+			log.Printf("events: failed to send %s event %s: %v", event.Type, event.ID, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+)
+
+// PubSubSink publishes events as the data payload of a Google Cloud Pub/Sub
+// message, with the CloudEvents type and subject carried as message
+// attributes.
+type PubSubSink struct {
+	topic *pubsub.Topic
+}
+
+// NewPubSubSink creates a PubSubSink publishing to the given project and
+// topic, e.g. from a resource of the form "pubsub://projects/p/topics/verify-events".
+func NewPubSubSink(ctx context.Context, projectID, topicID string) (*PubSubSink, error) {
+	client, err := pubsub.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pubsub client: %w", err)
+	}
+	return &PubSubSink{topic: client.Topic(topicID)}, nil
+}
+
+// Send publishes event and waits for the publish result, so a failed publish
+// is returned to the Publisher's worker, which logs it. Failed events are not
+// retried.
+func (s *PubSubSink) Send(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	result := s.topic.Publish(ctx, &pubsub.Message{
+		Data: data,
+		Attributes: map[string]string{
+			"type":    event.Type,
+			"subject": event.Subject,
+		},
+	})
+	if _, err := result.Get(ctx); err != nil {
+		return fmt.Errorf("pubsub publish failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SinkFor resolves an EventSink from a resource URI, as configured by
+// Config.EventSink:
+//
+//	webhook+https://example.com/hooks/verify?secret=...
+//	pubsub://projects/p/topics/verify-events
+//	kafka://broker1:9092,broker2:9092/verify-events
+func SinkFor(ctx context.Context, resource string) (EventSink, error) {
+	if resource == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(resource)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event sink resource %q: %w", resource, err)
+	}
+
+	switch {
+	case strings.HasPrefix(u.Scheme, "webhook+"):
+		secret := u.Query().Get("secret")
+		u.Scheme = strings.TrimPrefix(u.Scheme, "webhook+")
+		q := u.Query()
+		q.Del("secret")
+		u.RawQuery = q.Encode()
+		return NewWebhookSink(u.String(), []byte(secret)), nil
+	case u.Scheme == "pubsub":
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if u.Host != "projects" || len(parts) != 3 || parts[1] != "topics" {
+			return nil, fmt.Errorf("pubsub event sink must look like pubsub://projects/p/topics/t, got: %q", resource)
+		}
+		return NewPubSubSink(ctx, parts[0], parts[2])
+	case u.Scheme == "kafka":
+		brokers := strings.Split(u.Host, ",")
+		topic := strings.Trim(u.Path, "/")
+		return NewKafkaSink(brokers, topic)
+	default:
+		return nil, fmt.Errorf("unknown event sink scheme: %q", u.Scheme)
+	}
+}
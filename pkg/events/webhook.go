@@ -0,0 +1,72 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WebhookSink delivers events as an HTTP POST with a JSON CloudEvents body,
+// signed with HMAC-SHA256 over the raw body so the receiver can verify
+// authenticity.
+type WebhookSink struct {
+	url        string
+	secret     []byte
+	httpClient *http.Client
+}
+
+// NewWebhookSink creates a WebhookSink that posts to url, signing each
+// request body with secret.
+func NewWebhookSink(url string, secret []byte) *WebhookSink {
+	return &WebhookSink{url: url, secret: secret, httpClient: http.DefaultClient}
+}
+
+// Send posts event to the configured URL with an
+// "X-Verify-Signature: sha256=<hex>" header.
+func (w *WebhookSink) Send(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, w.secret)
+	mac.Write(body)
+	sig := hex.EncodeToString(mac.Sum(nil))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json")
+	req.Header.Set("X-Verify-Signature", "sha256="+sig)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+)
+
+// AWSKMS implements KeyManager against AWS KMS. keyID is a key ARN or alias,
+// e.g. "alias/verify-token-signing".
+type AWSKMS struct {
+	client *kms.KMS
+}
+
+// NewAWSKMS creates an AWSKMS client from the default AWS session (picking
+// up credentials and region from the environment or instance profile).
+func NewAWSKMS(ctx context.Context) (*AWSKMS, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create aws session: %w", err)
+	}
+	return &AWSKMS{client: kms.New(sess)}, nil
+}
+
+// Sign asks AWS KMS to sign digest using the asymmetric key identified by
+// keyID.
+func (a *AWSKMS) Sign(ctx context.Context, keyID string, digest []byte, hash crypto.Hash) ([]byte, error) {
+	algo, err := signingAlgorithmForHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := a.client.SignWithContext(ctx, &kms.SignInput{
+		KeyId:            &keyID,
+		Message:          digest,
+		MessageType:      awsStringPtr("DIGEST"),
+		SigningAlgorithm: &algo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aws kms Sign failed for %q: %w", keyID, err)
+	}
+	return out.Signature, nil
+}
+
+// PublicKey fetches and parses the public half of the asymmetric key.
+func (a *AWSKMS) PublicKey(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	out, err := a.client.GetPublicKeyWithContext(ctx, &kms.GetPublicKeyInput{KeyId: &keyID})
+	if err != nil {
+		return nil, fmt.Errorf("aws kms GetPublicKey failed for %q: %w", keyID, err)
+	}
+	return x509.ParsePKIXPublicKey(out.PublicKey)
+}
+
+func signingAlgorithmForHash(hash crypto.Hash) (string, error) {
+	switch hash {
+	case crypto.SHA256:
+		return kms.SigningAlgorithmSpecEcdsaSha256, nil
+	case crypto.SHA384:
+		return kms.SigningAlgorithmSpecEcdsaSha384, nil
+	default:
+		return "", fmt.Errorf("unsupported hash algorithm: %v", hash)
+	}
+}
+
+func awsStringPtr(s string) *string { return &s }
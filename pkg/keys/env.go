@@ -0,0 +1,74 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// EnvKeyManager signs with a PEM-encoded private key read directly from an
+// environment variable. This is the pre-rotation behavior, kept as the
+// default for local development and single-instance deployments that don't
+// need a KMS.
+type EnvKeyManager struct{}
+
+// NewEnvKeyManager creates an EnvKeyManager.
+func NewEnvKeyManager() *EnvKeyManager {
+	return &EnvKeyManager{}
+}
+
+// Sign signs digest with the ECDSA private key named by the keyID
+// environment variable.
+func (e *EnvKeyManager) Sign(ctx context.Context, keyID string, digest []byte, hash crypto.Hash) ([]byte, error) {
+	key, err := e.privateKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return ecdsa.SignASN1(rand.Reader, key, digest)
+}
+
+// PublicKey returns the public key half of the keyID environment variable.
+func (e *EnvKeyManager) PublicKey(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	key, err := e.privateKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return key.Public(), nil
+}
+
+func (e *EnvKeyManager) privateKey(envVar string) (*ecdsa.PrivateKey, error) {
+	raw := os.Getenv(envVar)
+	if raw == "" {
+		return nil, fmt.Errorf("environment variable %q is empty", envVar)
+	}
+
+	block, _ := pem.Decode([]byte(raw))
+	if block == nil {
+		return nil, fmt.Errorf("%q does not contain a valid PEM block", envVar)
+	}
+
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse EC private key from %q: %w", envVar, err)
+	}
+	return key, nil
+}
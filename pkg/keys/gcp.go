@@ -0,0 +1,71 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	kmspb "google.golang.org/genproto/googleapis/cloud/kms/v1"
+)
+
+// GoogleKMS implements KeyManager against Cloud KMS. keyID is the full
+// resource name of a cryptoKeyVersion, e.g.
+// "projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1".
+type GoogleKMS struct {
+	client *kms.KeyManagementClient
+}
+
+// NewGoogleKMS creates a GoogleKMS client using application default
+// credentials.
+func NewGoogleKMS(ctx context.Context) (*GoogleKMS, error) {
+	client, err := kms.NewKeyManagementClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cloud kms client: %w", err)
+	}
+	return &GoogleKMS{client: client}, nil
+}
+
+// Sign asks Cloud KMS to sign digest with the given cryptoKeyVersion. Only
+// the digest crosses the wire - the private key material never leaves KMS.
+func (g *GoogleKMS) Sign(ctx context.Context, keyID string, digest []byte, hash crypto.Hash) ([]byte, error) {
+	req := &kmspb.AsymmetricSignRequest{
+		Name:   keyID,
+		Digest: &kmspb.Digest{Digest: &kmspb.Digest_Sha256{Sha256: digest}},
+	}
+	resp, err := g.client.AsymmetricSign(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("cloud kms AsymmetricSign failed for %q: %w", keyID, err)
+	}
+	return resp.Signature, nil
+}
+
+// PublicKey fetches and parses the public half of the cryptoKeyVersion.
+func (g *GoogleKMS) PublicKey(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	resp, err := g.client.GetPublicKey(ctx, &kmspb.GetPublicKeyRequest{Name: keyID})
+	if err != nil {
+		return nil, fmt.Errorf("cloud kms GetPublicKey failed for %q: %w", keyID, err)
+	}
+
+	block, _ := pem.Decode([]byte(resp.Pem))
+	if block == nil {
+		return nil, fmt.Errorf("cloud kms returned an invalid PEM public key for %q", keyID)
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
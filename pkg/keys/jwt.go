@@ -0,0 +1,104 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// ecdsaSignatureSize is the byte length of each of r and s (half of the
+// concatenated r||s signature) for the P-256 curve every backend in this
+// package signs with.
+const ecdsaSignatureSize = 32
+
+// CurrentKeyID returns the "kid" of the key SignJWT (or Sign) would use if
+// called right now.
+func (s *KeySet) CurrentKeyID() (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.keys) == 0 {
+		return "", fmt.Errorf("key set has no active keys")
+	}
+	return s.keys[len(s.keys)-1].id, nil
+}
+
+// SignJWT signs claims as a compact ES256 JWT, embedding the "kid" of the
+// key that actually produced the signature in the header - the same kid a
+// relying party will find in this KeySet's published JWKS.
+func (s *KeySet) SignJWT(ctx context.Context, claims interface{}) (string, error) {
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal jwt claims: %w", err)
+	}
+
+	// Rotation can happen between picking a kid for the header and actually
+	// signing; retry once with whichever kid really signed.
+	const maxAttempts = 2
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		kid, err := s.CurrentKeyID()
+		if err != nil {
+			return "", err
+		}
+
+		headerJSON, err := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT", "kid": kid})
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal jwt header: %w", err)
+		}
+
+		signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." +
+			base64.RawURLEncoding.EncodeToString(claimsJSON)
+		digest := sha256.Sum256([]byte(signingInput))
+
+		der, signedKid, err := s.Sign(ctx, digest[:], crypto.SHA256)
+		if err != nil {
+			return "", err
+		}
+		if signedKid != kid {
+			continue
+		}
+
+		raw, err := ecdsaASN1ToRaw(der)
+		if err != nil {
+			return "", err
+		}
+		return signingInput + "." + base64.RawURLEncoding.EncodeToString(raw), nil
+	}
+
+	return "", fmt.Errorf("failed to sign jwt: key rotated on every attempt")
+}
+
+// ecdsaASN1ToRaw converts an ASN.1 DER-encoded ECDSA signature (as returned
+// by every KeyManager in this package) into the fixed-width r||s form a JWT
+// ES256 signature requires.
+func ecdsaASN1ToRaw(der []byte) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("failed to parse ASN.1 ECDSA signature: %w", err)
+	}
+
+	out := make([]byte, ecdsaSignatureSize*2)
+	sig.R.FillBytes(out[:ecdsaSignatureSize])
+	sig.S.FillBytes(out[ecdsaSignatureSize:])
+	return out, nil
+}
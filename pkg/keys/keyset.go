@@ -0,0 +1,137 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"fmt"
+	"sync"
+)
+
+// JWKS is the JSON Web Key Set document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is the subset of RFC 7517 fields this server publishes for EC keys.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+}
+
+// activeKey pairs a KeyManager-resolved key ID ("kid") with the manager that
+// can sign against it.
+type activeKey struct {
+	id string
+	km KeyManager
+}
+
+// KeySet holds every currently-active signing key for a single purpose
+// (token signing, or certificate signing). Multiple keys can be active at
+// once so that rotation is zero downtime: new signatures are always made
+// with the newest key, while older keys stay in the published JWKS so
+// verifiers keep accepting them. Keys are never removed from a KeySet.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []activeKey // ordered oldest to newest; keys[len(keys)-1] is current
+}
+
+// NewKeySet creates a KeySet whose only active key is the one resolved from
+// defaultResource (typically Config.TokenSigningKey or
+// Config.CertificateSigningKey).
+func NewKeySet(ctx context.Context, defaultResource string) (*KeySet, error) {
+	km, id, err := KeyManagerFor(ctx, defaultResource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve default signing key: %w", err)
+	}
+	return &KeySet{keys: []activeKey{{id: id, km: km}}}, nil
+}
+
+// Rotate adds resource as the new current signing key. Previously active
+// keys remain valid for verification and stay in the JWKS document; KeySet
+// has no method to remove them.
+func (s *KeySet) Rotate(ctx context.Context, resource string) error {
+	km, id, err := KeyManagerFor(ctx, resource)
+	if err != nil {
+		return fmt.Errorf("failed to resolve rotated signing key: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append(s.keys, activeKey{id: id, km: km})
+	return nil
+}
+
+// Sign signs digest with the newest active key and returns both the
+// signature and the "kid" that produced it, so the caller can embed it in
+// the token/certificate header.
+func (s *KeySet) Sign(ctx context.Context, digest []byte, hash crypto.Hash) (sig []byte, kid string, err error) {
+	s.mu.RLock()
+	if len(s.keys) == 0 {
+		s.mu.RUnlock()
+		return nil, "", fmt.Errorf("key set has no active keys")
+	}
+	current := s.keys[len(s.keys)-1]
+	s.mu.RUnlock()
+
+	sig, err = current.km.Sign(ctx, current.id, digest, hash)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign with key %q: %w", current.id, err)
+	}
+	return sig, current.id, nil
+}
+
+// PublishJWKS builds the JWKS document containing every currently active
+// key, for serving at /.well-known/jwks.json.
+func (s *KeySet) PublishJWKS(ctx context.Context) (*JWKS, error) {
+	s.mu.RLock()
+	keys := append([]activeKey(nil), s.keys...)
+	s.mu.RUnlock()
+
+	out := make([]JWK, 0, len(keys))
+	for _, k := range keys {
+		pub, err := k.km.PublicKey(ctx, k.id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch public key for %q: %w", k.id, err)
+		}
+		ecKey, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("key %q is not an EC public key", k.id)
+		}
+		// Zero-pad X/Y to the curve's field size - big.Int.Bytes strips
+		// leading zeroes, which would otherwise intermittently produce a
+		// JWK coordinate shorter than strict JWKS consumers expect.
+		x := make([]byte, ecdsaSignatureSize)
+		y := make([]byte, ecdsaSignatureSize)
+		ecKey.X.FillBytes(x)
+		ecKey.Y.FillBytes(y)
+		out = append(out, JWK{
+			Kty: "EC",
+			Crv: ecKey.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(x),
+			Y:   base64.RawURLEncoding.EncodeToString(y),
+			Use: "sig",
+			Kid: k.id,
+		})
+	}
+	return &JWKS{Keys: out}, nil
+}
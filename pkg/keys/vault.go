@@ -0,0 +1,127 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// VaultKeyManager implements KeyManager against HashiCorp Vault's Transit
+// secrets engine. keyID is "<mount>/<transitKeyName>" or
+// "<mount>/<transitKeyName>?version=N" to pin a specific key version, as
+// produced by KeyManagerFor from a vault:// resource URI.
+type VaultKeyManager struct {
+	client *vaultapi.Client
+}
+
+// NewVaultKeyManager creates a VaultKeyManager using the ambient Vault
+// client config (VAULT_ADDR and VAULT_TOKEN). No AppRole or Kubernetes login
+// is performed.
+func NewVaultKeyManager(ctx context.Context) (*VaultKeyManager, error) {
+	client, err := vaultapi.NewClient(vaultapi.DefaultConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault client: %w", err)
+	}
+	return &VaultKeyManager{client: client}, nil
+}
+
+// Sign signs digest with Transit's /<mount>/sign/<key> endpoint.
+func (v *VaultKeyManager) Sign(ctx context.Context, keyID string, digest []byte, hash crypto.Hash) ([]byte, error) {
+	mount, keyName, version := splitVaultKeyID(keyID)
+
+	data := map[string]interface{}{
+		"input":     base64.StdEncoding.EncodeToString(digest),
+		"prehashed": true,
+		// Every key this package signs with is ECDSA P-256 (see
+		// ecdsaASN1ToRaw in jwt.go and the *ecdsa.PublicKey cast in
+		// keyset.go's PublishJWKS), never RSA, so "signature_algorithm"
+		// (an RSA-only pkcs1v15/pss choice) doesn't apply here.
+		// "marshaling_algorithm" is Vault's EC equivalent; asn1 is already
+		// the default, but set it explicitly since ecdsaASN1ToRaw requires it.
+		"marshaling_algorithm": "asn1",
+	}
+	if version != "" {
+		data["key_version"] = version
+	}
+
+	secret, err := v.client.Logical().WriteWithContext(ctx, mount+"/sign/"+keyName, data)
+	if err != nil {
+		return nil, fmt.Errorf("vault transit sign failed for %q: %w", keyID, err)
+	}
+
+	sig, _ := secret.Data["signature"].(string)
+	// Vault signatures are prefixed "vault:v<version>:<base64>".
+	parts := strings.SplitN(sig, ":", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("unexpected vault signature format: %q", sig)
+	}
+	return base64.StdEncoding.DecodeString(parts[2])
+}
+
+// PublicKey fetches the public key for the given Transit key (and optional
+// version) via /<mount>/keys/<key>.
+func (v *VaultKeyManager) PublicKey(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	mount, keyName, version := splitVaultKeyID(keyID)
+
+	secret, err := v.client.Logical().ReadWithContext(ctx, mount+"/keys/"+keyName)
+	if err != nil {
+		return nil, fmt.Errorf("vault transit read failed for %q: %w", keyID, err)
+	}
+
+	keys, _ := secret.Data["keys"].(map[string]interface{})
+	if version != "" {
+		pinned, ok := keys[version]
+		if !ok {
+			return nil, fmt.Errorf("no public key found for %q", keyID)
+		}
+		keys = map[string]interface{}{version: pinned}
+	}
+	for _, k := range keys {
+		entry, _ := k.(map[string]interface{})
+		pemStr, _ := entry["public_key"].(string)
+		if pemStr == "" {
+			continue
+		}
+		block, _ := pem.Decode([]byte(pemStr))
+		if block == nil {
+			continue
+		}
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("no public key found for %q", keyID)
+}
+
+// splitVaultKeyID parses a "<mount>/<name>[?version=N]" keyID, as produced
+// by KeyManagerFor, into its Transit mount, key name, and optional pinned
+// version.
+func splitVaultKeyID(keyID string) (mount, name, version string) {
+	base := keyID
+	if idx := strings.Index(keyID, "?version="); idx != -1 {
+		base, version = keyID[:idx], keyID[idx+len("?version="):]
+	}
+	idx := strings.LastIndex(base, "/")
+	if idx == -1 {
+		return "", base, version
+	}
+	return base[:idx], base[idx+1:], version
+}
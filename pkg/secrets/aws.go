@@ -0,0 +1,56 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// AWSSecretManager implements SecretManager against AWS Secrets Manager. A
+// secret name is the secret's ARN or friendly name, e.g.
+// "prod/verify/token_signing".
+type AWSSecretManager struct {
+	client *secretsmanager.SecretsManager
+}
+
+// NewAWSSecretManager creates an AWSSecretManager from the default AWS
+// session (credentials and region from the environment or instance
+// profile).
+func NewAWSSecretManager(ctx context.Context) (*AWSSecretManager, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create aws session: %w", err)
+	}
+	return &AWSSecretManager{client: secretsmanager.New(sess)}, nil
+}
+
+// GetSecretValue fetches the current value of the named secret.
+func (a *AWSSecretManager) GetSecretValue(ctx context.Context, name string) (string, error) {
+	out, err := a.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("aws secrets manager GetSecretValue failed for %q: %w", name, err)
+	}
+	if out.SecretString != nil {
+		return *out.SecretString, nil
+	}
+	return string(out.SecretBinary), nil
+}
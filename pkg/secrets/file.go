@@ -0,0 +1,41 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// FileSecretManager implements SecretManager by reading a file from disk,
+// for secrets projected into a pod by a Kubernetes Secret volume or CSI
+// driver (e.g. /run/secrets/csrf). The name is the absolute file path.
+type FileSecretManager struct{}
+
+// NewFileSecretManager creates a FileSecretManager.
+func NewFileSecretManager() *FileSecretManager {
+	return &FileSecretManager{}
+}
+
+// GetSecretValue reads and trims the contents of the file at name.
+func (f *FileSecretManager) GetSecretValue(ctx context.Context, name string) (string, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file %q: %w", name, err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
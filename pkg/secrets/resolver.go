@@ -0,0 +1,104 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sethvargo/go-envconfig/pkg/envconfig"
+
+	upstream "github.com/google/exposure-notifications-server/pkg/secrets"
+)
+
+// Resolver returns a mutator that resolves env values addressed by a
+// backend-specific URI scheme, regardless of the server's default
+// SECRET_MANAGER_TYPE:
+//
+//	vault://secret/data/verify/csrf#value
+//	awssm://prod/verify/token_signing
+//	file:///run/secrets/csrf
+//
+// Any value that doesn't match one of those schemes (including the existing
+// bare "secret://" form) is left for defaultResolver to handle against the
+// server's configured default SecretManager. cacheTTL is applied uniformly
+// across every scheme-addressed backend here, matching the caching the
+// default SecretManager gets from SECRET_CACHE_TTL. Each backend's
+// SecretManager (and its cache) is built at most once and reused for every
+// subsequent resolution, the same way the default SecretManager is built
+// once in NewWith - not once per value resolved.
+func Resolver(defaultResolver envconfig.MutatorFunc, cacheTTL time.Duration) envconfig.MutatorFunc {
+	vault := &memoizedManager{build: func(ctx context.Context) (SecretManager, error) { return NewVaultSecretManager(ctx) }}
+	awssm := &memoizedManager{build: func(ctx context.Context) (SecretManager, error) { return NewAWSSecretManager(ctx) }}
+	file := &memoizedManager{build: func(ctx context.Context) (SecretManager, error) { return NewFileSecretManager(), nil }}
+
+	return func(ctx context.Context, key, value string) (string, error) {
+		switch {
+		case strings.HasPrefix(value, "vault://"):
+			sm, err := vault.get(ctx, cacheTTL)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", key, err)
+			}
+			return sm.GetSecretValue(ctx, strings.TrimPrefix(value, "vault://"))
+		case strings.HasPrefix(value, "awssm://"):
+			sm, err := awssm.get(ctx, cacheTTL)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", key, err)
+			}
+			return sm.GetSecretValue(ctx, strings.TrimPrefix(value, "awssm://"))
+		case strings.HasPrefix(value, "file://"):
+			sm, err := file.get(ctx, cacheTTL)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", key, err)
+			}
+			return sm.GetSecretValue(ctx, strings.TrimPrefix(value, "file://"))
+		default:
+			return defaultResolver(ctx, key, value)
+		}
+	}
+}
+
+// memoizedManager builds its SecretManager (wrapped in upstream's cache, if
+// a TTL is configured) at most once, the first time get is called, and
+// hands back that same instance on every later call.
+type memoizedManager struct {
+	build func(ctx context.Context) (SecretManager, error)
+
+	once sync.Once
+	sm   SecretManager
+	err  error
+}
+
+func (m *memoizedManager) get(ctx context.Context, cacheTTL time.Duration) (SecretManager, error) {
+	m.once.Do(func() {
+		sm, err := m.build(ctx)
+		if err != nil {
+			m.err = err
+			return
+		}
+		if cacheTTL <= 0 {
+			m.sm = sm
+			return
+		}
+		m.sm, m.err = upstream.WrapCacher(ctx, sm, cacheTTL)
+		if m.err != nil {
+			m.err = fmt.Errorf("failed to wrap secret manager with cache: %w", m.err)
+		}
+	})
+	return m.sm, m.err
+}
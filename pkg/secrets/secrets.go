@@ -0,0 +1,49 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secrets extends the upstream secrets.SecretManager with adapters
+// for backends other than GCP Secret Manager, so the same binary can
+// resolve secret:// (and vault://, awssm://, file://) resources on GCP, AWS,
+// or an on-prem Kubernetes cluster without recompilation.
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	upstream "github.com/google/exposure-notifications-server/pkg/secrets"
+)
+
+// SecretManager resolves a secret value by name. It is the same interface
+// upstream's secrets.WrapCacher and secrets.Resolver already operate on, so
+// adapters here are drop-in replacements for upstream's GCP client.
+type SecretManager = upstream.SecretManager
+
+// ManagerFor returns the SecretManager for typ. "vault" and "awssm" are
+// handled locally; anything else (including the existing GCP types) is
+// delegated to the upstream resolver unchanged.
+func ManagerFor(ctx context.Context, typ string) (SecretManager, error) {
+	switch typ {
+	case "vault":
+		return NewVaultSecretManager(ctx)
+	case "awssm":
+		return NewAWSSecretManager(ctx)
+	default:
+		sm, err := upstream.SecretManagerFor(ctx, typ)
+		if err != nil {
+			return nil, fmt.Errorf("unable to connect to secret manager %q: %w", typ, err)
+		}
+		return sm, nil
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// VaultSecretManager implements SecretManager against HashiCorp Vault's KV
+// v2 secrets engine. A secret name looks like "secret/data/verify/csrf#value",
+// where the path before "#" is the Vault path and the field after "#" is the
+// key within that secret's data (defaulting to "value" if omitted).
+type VaultSecretManager struct {
+	client *vaultapi.Client
+}
+
+// NewVaultSecretManager creates a VaultSecretManager from the ambient Vault
+// client config (VAULT_ADDR and VAULT_TOKEN), the same way
+// pkg/keys.NewVaultKeyManager does. No AppRole or Kubernetes service account
+// login is performed.
+func NewVaultSecretManager(ctx context.Context) (*VaultSecretManager, error) {
+	client, err := vaultapi.NewClient(vaultapi.DefaultConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault client: %w", err)
+	}
+	return &VaultSecretManager{client: client}, nil
+}
+
+// GetSecretValue reads the given Vault path and returns the requested field.
+func (v *VaultSecretManager) GetSecretValue(ctx context.Context, name string) (string, error) {
+	path, field := name, "value"
+	if idx := strings.Index(name, "#"); idx != -1 {
+		path, field = name[:idx], name[idx+1:]
+	}
+
+	secret, err := v.client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("vault read failed for %q: %w", path, err)
+	}
+	if secret == nil {
+		return "", fmt.Errorf("no secret found at %q", path)
+	}
+
+	data, _ := secret.Data["data"].(map[string]interface{})
+	if data == nil {
+		// Fall back to KV v1, which has no nested "data" wrapper.
+		data = secret.Data
+	}
+
+	val, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %q has no string field %q", path, field)
+	}
+	return val, nil
+}